@@ -0,0 +1,261 @@
+package zabbix
+
+import (
+	"fmt"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// HostTagOperators zabbix host tag filter operators
+var HostTagOperators = map[string]int{
+	"contains":     0,
+	"equals":       1,
+	"not_contains": 2,
+	"not_equals":   3,
+}
+
+var hostFilterTagSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"tag": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"value": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"operator": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "contains",
+		},
+	},
+}
+
+var hostFilterSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"group": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"tag": &schema.Schema{
+			Type:     schema.TypeList,
+			Elem:     hostFilterTagSchema,
+			Optional: true,
+		},
+	},
+}
+
+func dataSourceZabbixHost() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceZabbixHostRead,
+		Schema: map[string]*schema.Schema{
+			"host": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"host_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"monitored": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"filter": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     hostFilterSchema,
+				Optional: true,
+				MaxItems: 1,
+			},
+			"interfaces": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     interfaceSchema,
+				Computed: true,
+			},
+			"groups": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"templates": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"macro": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     macroSchema,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceZabbixHostSearchParams(d *schema.ResourceData, api *zabbix.API) (zabbix.Params, error) {
+	params := zabbix.Params{
+		"output":       "extend",
+		"selectMacros": "extend",
+	}
+
+	filterField := map[string]interface{}{}
+
+	if host, ok := d.GetOk("host"); ok {
+		filterField["host"] = host.(string)
+	}
+
+	if name, ok := d.GetOk("name"); ok {
+		filterField["name"] = name.(string)
+	}
+
+	if hostID, ok := d.GetOk("host_id"); ok {
+		filterField["hostid"] = hostID.(string)
+	}
+
+	if len(filterField) > 0 {
+		params["filter"] = filterField
+	}
+
+	if group, ok := d.GetOk("filter.0.group"); ok {
+		groupName := group.(string)
+
+		groups, err := api.HostGroupsGet(zabbix.Params{
+			"output": "extend",
+			"filter": map[string]interface{}{
+				"name": []string{groupName},
+			},
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("Host group %s doesnt exist in zabbix server", groupName)
+		}
+
+		params["groupids"] = []string{groups[0].GroupID}
+	}
+
+	tagCount := d.Get("filter.0.tag.#").(int)
+
+	if tagCount > 0 {
+		tags := make([]map[string]interface{}, tagCount)
+
+		for i := 0; i < tagCount; i++ {
+			prefix := fmt.Sprintf("filter.0.tag.%d.", i)
+
+			operator := d.Get(prefix + "operator").(string)
+
+			operatorID, ok := HostTagOperators[operator]
+
+			if !ok {
+				return nil, fmt.Errorf("%s isnt valid host tag operator", operator)
+			}
+
+			tags[i] = map[string]interface{}{
+				"tag":      d.Get(prefix + "tag").(string),
+				"value":    d.Get(prefix + "value").(string),
+				"operator": operatorID,
+			}
+		}
+
+		params["tags"] = tags
+	}
+
+	if len(filterField) == 0 && params["groupids"] == nil && params["tags"] == nil {
+		return nil, fmt.Errorf("at least one of host, name, host_id or filter must be set")
+	}
+
+	return params, nil
+}
+
+func dataSourceZabbixHostRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	params, err := dataSourceZabbixHostSearchParams(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	hosts, err := api.HostsGet(params)
+
+	if err != nil {
+		return err
+	}
+
+	if len(hosts) == 0 {
+		return fmt.Errorf("no host found matching the given filter")
+	}
+
+	if len(hosts) > 1 {
+		return fmt.Errorf("%d hosts found matching the given filter, expected one", len(hosts))
+	}
+
+	host := hosts[0]
+
+	d.SetId(host.HostID)
+	d.Set("host", host.Host)
+	d.Set("host_id", host.HostID)
+	d.Set("name", host.Name)
+	d.Set("monitored", host.Status == 0)
+
+	interfaceParams := zabbix.Params{
+		"output":  "extend",
+		"hostids": []string{host.HostID},
+	}
+
+	interfaces, err := api.HostInterfacesGet(interfaceParams)
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("interfaces", flattenHostInterfaces(interfaces))
+
+	templates, err := api.TemplatesGet(interfaceParams)
+
+	if err != nil {
+		return err
+	}
+
+	templateNames := make([]string, len(templates))
+
+	for i, t := range templates {
+		templateNames[i] = t.Host
+	}
+
+	d.Set("templates", templateNames)
+
+	groups, err := api.HostGroupsGet(interfaceParams)
+
+	if err != nil {
+		return err
+	}
+
+	groupNames := make([]string, len(groups))
+
+	for i, g := range groups {
+		groupNames[i] = g.Name
+	}
+
+	d.Set("groups", groupNames)
+
+	terraformMacros, err := createTerraformMacroOnHost(d, host)
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("macro", terraformMacros)
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// DiscoveryRuleTypes zabbix item types usable as a low-level discovery rule
+var DiscoveryRuleTypes = map[string]int{
+	"agent":         0,
+	"snmp":          1,
+	"trapper":       2,
+	"simple":        3,
+	"internal":      4,
+	"zabbix_active": 7,
+}
+
+func resourceZabbixDiscoveryRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixDiscoveryRuleCreate,
+		Read:   resourceZabbixDiscoveryRuleRead,
+		Update: resourceZabbixDiscoveryRuleUpdate,
+		Delete: resourceZabbixDiscoveryRuleDelete,
+		Schema: map[string]*schema.Schema{
+			"host_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the host or template the discovery rule belongs to.",
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Discovery rule key, e.g. vfs.fs.discovery.",
+			},
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "agent",
+			},
+			"interface_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"delay": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "1h",
+			},
+		},
+	}
+}
+
+func createDiscoveryRuleObj(d *schema.ResourceData) (*zabbix.DiscoveryRule, error) {
+	ruleType, ok := DiscoveryRuleTypes[d.Get("type").(string)]
+
+	if !ok {
+		return nil, fmt.Errorf("%s isnt valid discovery rule type", d.Get("type").(string))
+	}
+
+	rule := zabbix.DiscoveryRule{
+		HostID:      d.Get("host_id").(string),
+		Name:        d.Get("name").(string),
+		Key:         d.Get("key").(string),
+		Type:        ruleType,
+		Delay:       d.Get("delay").(string),
+		InterfaceID: d.Get("interface_id").(string),
+	}
+
+	return &rule, nil
+}
+
+func resourceZabbixDiscoveryRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	rule, err := createDiscoveryRuleObj(d)
+
+	if err != nil {
+		return err
+	}
+
+	rules := zabbix.DiscoveryRules{*rule}
+
+	err = api.DiscoveryRulesCreate(rules)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Created discovery rule id is %s", rules[0].ItemID)
+
+	d.SetId(rules[0].ItemID)
+
+	return nil
+}
+
+func resourceZabbixDiscoveryRuleRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	log.Printf("[DEBUG] Will read discovery rule with id %s", d.Id())
+
+	rule, err := api.DiscoveryRuleGetByID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("host_id", rule.HostID)
+	d.Set("name", rule.Name)
+	d.Set("key", rule.Key)
+	d.Set("interface_id", rule.InterfaceID)
+	d.Set("delay", rule.Delay)
+
+	for name, typeID := range DiscoveryRuleTypes {
+		if typeID == rule.Type {
+			d.Set("type", name)
+			break
+		}
+	}
+
+	return nil
+}
+
+func resourceZabbixDiscoveryRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	rule, err := createDiscoveryRuleObj(d)
+
+	if err != nil {
+		return err
+	}
+
+	rule.ItemID = d.Id()
+
+	rules := zabbix.DiscoveryRules{*rule}
+
+	err = api.DiscoveryRulesUpdate(rules)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Updated discovery rule id is %s", rules[0].ItemID)
+
+	return nil
+}
+
+func resourceZabbixDiscoveryRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	return api.DiscoveryRulesDeleteByIds([]string{d.Id()})
+}
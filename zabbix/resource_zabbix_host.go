@@ -4,7 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"regexp"
 
 	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,39 +18,109 @@ var HostInterfaceTypes = map[string]zabbix.InterfaceType{
 	"jmx":   4,
 }
 
+// SNMPSecurityLevels zabbix SNMPv3 security levels
+var SNMPSecurityLevels = map[string]int{
+	"noauthnopriv": 0,
+	"authnopriv":   1,
+	"authpriv":     2,
+}
+
+// SNMPAuthProtocols zabbix SNMPv3 authentication protocols
+var SNMPAuthProtocols = map[string]int{
+	"md5":  0,
+	"sha1": 1,
+}
+
+// SNMPPrivProtocols zabbix SNMPv3 privacy protocols
+var SNMPPrivProtocols = map[string]int{
+	"des": 0,
+	"aes": 1,
+}
+
 var interfaceSchema *schema.Resource = &schema.Resource{
 	Schema: map[string]*schema.Schema{
 		"dns": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
-			ForceNew: true,
 		},
 		"ip": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
-			ForceNew: true,
 		},
 		"main": &schema.Schema{
 			Type:     schema.TypeBool,
 			Required: true,
-			ForceNew: true,
 		},
 		"port": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
 			Default:  "10050",
-			ForceNew: true,
 		},
 		"type": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
 			Default:  "agent",
-			ForceNew: true,
 		},
 		"interface_id": &schema.Schema{
 			Type:     schema.TypeString,
 			Computed: true,
-			ForceNew: true,
+		},
+		"details": &schema.Schema{
+			Type:     schema.TypeList,
+			Elem:     interfaceDetailsSchema,
+			Optional: true,
+			MaxItems: 1,
+		},
+	},
+}
+
+// interfaceDetailsSchema holds the SNMP specific fields of a host interface,
+// only meaningful when the interface type is "snmp"
+var interfaceDetailsSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"version": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  2,
+		},
+		"bulk": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"community": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"securityname": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"securitylevel": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"authpassphrase": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"privpassphrase": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"authprotocol": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"privprotocol": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"contextname": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
 		},
 	},
 }
@@ -85,14 +155,10 @@ func resourceZabbixHost() *schema.Resource {
 				Default:  true,
 				Optional: true,
 			},
-			//any changes to interface will trigger recreate, zabbix api kinda doesn't
-			//work nicely, interface can get linked to various things and replacement
-			//simply doesn't work
 			"interfaces": &schema.Schema{
 				Type:     schema.TypeList,
 				Elem:     interfaceSchema,
 				Required: true,
-				ForceNew: true,
 			},
 			"groups": &schema.Schema{
 				Type:     schema.TypeSet,
@@ -105,8 +171,8 @@ func resourceZabbixHost() *schema.Resource {
 				Optional: true,
 			},
 			"macro": &schema.Schema{
-				Type:        schema.TypeMap,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Type:        schema.TypeSet,
+				Elem:        macroSchema,
 				Optional:    true,
 				Description: "User macros for the host.",
 			},
@@ -114,52 +180,229 @@ func resourceZabbixHost() *schema.Resource {
 	}
 }
 
+// MacroTypes zabbix user macro types
+var MacroTypes = map[string]int{
+	"text":   0,
+	"secret": 1,
+	"vault":  2,
+}
+
+// zabbixMacroSecretPlaceholder is what the API returns instead of the real
+// value for macros of type "secret", so the provider never diffs against it
+const zabbixMacroSecretPlaceholder = "******"
+
+var macroSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"value": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"type": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "text",
+		},
+		"description": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	},
+}
+
 func getInterfaces(d *schema.ResourceData) (zabbix.HostInterfaces, error) {
-	interfaceCount := d.Get("interfaces.#").(int)
+	configInterfaces := d.Get("interfaces").([]interface{})
 
-	interfaces := make(zabbix.HostInterfaces, interfaceCount)
+	interfaces := make(zabbix.HostInterfaces, len(configInterfaces))
 
-	for i := 0; i < interfaceCount; i++ {
-		prefix := fmt.Sprintf("interfaces.%d.", i)
+	for i, raw := range configInterfaces {
+		iface, err := interfaceFromResourceData(raw.(map[string]interface{}))
 
-		interfaceType := d.Get(prefix + "type").(string)
+		if err != nil {
+			return nil, err
+		}
 
-		typeID, ok := HostInterfaceTypes[interfaceType]
+		interfaces[i] = iface
+	}
 
-		if !ok {
-			return nil, fmt.Errorf("%s isnt valid interface type", interfaceType)
+	return interfaces, nil
+}
+
+// interfaceKey returns a stable identity for an interface: its interface_id
+// when already known to zabbix, otherwise the type+main+ip+dns tuple used to
+// match config entries against state when no id has been assigned yet
+func interfaceKey(m map[string]interface{}) string {
+	if id, ok := m["interface_id"].(string); ok && id != "" {
+		return "id:" + id
+	}
+
+	return fmt.Sprintf("tuple:%s:%v:%s:%s", m["type"], m["main"], m["ip"], m["dns"])
+}
+
+func interfaceFromResourceData(m map[string]interface{}) (zabbix.HostInterface, error) {
+	interfaceType := m["type"].(string)
+
+	typeID, ok := HostInterfaceTypes[interfaceType]
+
+	if !ok {
+		return zabbix.HostInterface{}, fmt.Errorf("%s isnt valid interface type", interfaceType)
+	}
+
+	ip := m["ip"].(string)
+	dns := m["dns"].(string)
+
+	if ip == "" && dns == "" {
+		return zabbix.HostInterface{}, errors.New("Atleast one of two dns or ip must be set")
+	}
+
+	useip := 1
+
+	if ip == "" {
+		useip = 0
+	}
+
+	main := 1
+
+	if !m["main"].(bool) {
+		main = 0
+	}
+
+	iface := zabbix.HostInterface{
+		InterfaceID: m["interface_id"].(string),
+		IP:          ip,
+		DNS:         dns,
+		Main:        main,
+		Port:        m["port"].(string),
+		Type:        typeID,
+		UseIP:       useip,
+	}
+
+	if interfaceType == "snmp" {
+		rawDetails, _ := m["details"].([]interface{})
+
+		details, err := interfaceDetailsFromResourceData(rawDetails)
+
+		if err != nil {
+			return zabbix.HostInterface{}, err
 		}
 
-		ip := d.Get(prefix + "ip").(string)
-		dns := d.Get(prefix + "dns").(string)
+		iface.Details = details
+	}
+
+	return iface, nil
+}
+
+func interfaceDetailsFromResourceData(raw []interface{}) (zabbix.HostInterfaceDetails, error) {
+	if len(raw) == 0 {
+		return zabbix.HostInterfaceDetails{}, nil
+	}
+
+	m := raw[0].(map[string]interface{})
+
+	bulk := 0
+
+	if m["bulk"].(bool) {
+		bulk = 1
+	}
+
+	details := zabbix.HostInterfaceDetails{
+		Version:   m["version"].(int),
+		Bulk:      bulk,
+		Community: m["community"].(string),
+	}
+
+	if details.Version == 3 {
+		securityLevel := m["securitylevel"].(string)
+
+		if securityLevel != "" {
+			levelID, ok := SNMPSecurityLevels[securityLevel]
 
-		if ip == "" && dns == "" {
-			return nil, errors.New("Atleast one of two dns or ip must be set")
+			if !ok {
+				return details, fmt.Errorf("%s isnt valid SNMPv3 security level", securityLevel)
+			}
+
+			details.SecurityLevel = levelID
 		}
 
-		useip := 1
+		if authProtocol := m["authprotocol"].(string); authProtocol != "" {
+			protocolID, ok := SNMPAuthProtocols[authProtocol]
 
-		if ip == "" {
-			useip = 0
+			if !ok {
+				return details, fmt.Errorf("%s isnt valid SNMPv3 auth protocol", authProtocol)
+			}
+
+			details.AuthProtocol = protocolID
 		}
 
-		main := 1
+		if privProtocol := m["privprotocol"].(string); privProtocol != "" {
+			protocolID, ok := SNMPPrivProtocols[privProtocol]
+
+			if !ok {
+				return details, fmt.Errorf("%s isnt valid SNMPv3 priv protocol", privProtocol)
+			}
 
-		if !d.Get(prefix + "main").(bool) {
-			main = 1
+			details.PrivProtocol = protocolID
 		}
 
-		interfaces[i] = zabbix.HostInterface{
-			IP:    ip,
-			DNS:   dns,
-			Main:  main,
-			Port:  d.Get(prefix + "port").(string),
-			Type:  typeID,
-			UseIP: useip,
+		details.SecurityName = m["securityname"].(string)
+		details.AuthPassphrase = m["authpassphrase"].(string)
+		details.PrivPassphrase = m["privpassphrase"].(string)
+		details.ContextName = m["contextname"].(string)
+	}
+
+	return details, nil
+}
+
+// diffInterfaces compares the interfaces in state against the ones in config
+// and splits them into the ones to create, update in place and delete, so
+// resourceZabbixHostUpdate never has to recreate a host just to change an
+// interface's ip, port or dns
+func diffInterfaces(d *schema.ResourceData) (create zabbix.HostInterfaces, update zabbix.HostInterfaces, deleteIDs []string, err error) {
+	oldRaw, newRaw := d.GetChange("interfaces")
+
+	return diffInterfaceLists(oldRaw.([]interface{}), newRaw.([]interface{}))
+}
+
+// diffInterfaceLists is the pure matching logic behind diffInterfaces, split
+// out so it can be exercised directly without building a *schema.ResourceData
+func diffInterfaceLists(oldList []interface{}, newList []interface{}) (create zabbix.HostInterfaces, update zabbix.HostInterfaces, deleteIDs []string, err error) {
+	oldByKey := make(map[string]map[string]interface{})
+
+	for _, raw := range oldList {
+		m := raw.(map[string]interface{})
+		oldByKey[interfaceKey(m)] = m
+	}
+
+	for _, raw := range newList {
+		m := raw.(map[string]interface{})
+
+		iface, ferr := interfaceFromResourceData(m)
+
+		if ferr != nil {
+			return nil, nil, nil, ferr
+		}
+
+		key := interfaceKey(m)
+
+		if old, ok := oldByKey[key]; ok {
+			iface.InterfaceID = old["interface_id"].(string)
+			update = append(update, iface)
+			delete(oldByKey, key)
+			continue
 		}
+
+		create = append(create, iface)
 	}
 
-	return interfaces, nil
+	for _, old := range oldByKey {
+		deleteIDs = append(deleteIDs, old["interface_id"].(string))
+	}
+
+	return create, update, deleteIDs, nil
 }
 
 func getHostGroups(d *schema.ResourceData, api *zabbix.API) (zabbix.HostGroupIDs, error) {
@@ -396,8 +639,16 @@ func resourceZabbixHostRead(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	d.Set("groups", groupNames)
-	
-	terraformMacros, err := createTerraformMacroOnHost(*host)
+
+	interfaces, err := api.HostInterfacesGet(params)
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("interfaces", flattenHostInterfaces(interfaces))
+
+	terraformMacros, err := createTerraformMacroOnHost(d, *host)
 	if err != nil {
 		return err
 	}
@@ -417,8 +668,8 @@ func resourceZabbixHostUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	host.HostID = d.Id()
 
-	//interfaces can't be updated, changes will trigger recreate
-	//sending previous values will also fail the update
+	//interfaces are reconciled separately below via hostinterface.create/update/delete
+	//so linked items/triggers survive an ip/port/dns change instead of forcing a recreate
 	host.Interfaces = nil
 
 	hosts := zabbix.Hosts{*host}
@@ -429,9 +680,43 @@ func resourceZabbixHostUpdate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	log.Printf("[DEBUG] Created host id is %s", hosts[0].HostID)
+	log.Printf("[DEBUG] Updated host id is %s", hosts[0].HostID)
 
-	return nil
+	create, update, deleteIDs, err := diffInterfaces(d)
+
+	if err != nil {
+		return err
+	}
+
+	if len(deleteIDs) > 0 {
+		log.Printf("[DEBUG] Deleting interfaces %v", deleteIDs)
+
+		if err := api.HostInterfacesDeleteByIds(deleteIDs); err != nil {
+			return err
+		}
+	}
+
+	if len(update) > 0 {
+		log.Printf("[DEBUG] Updating interfaces %v", update)
+
+		if err := api.HostInterfacesUpdate(update); err != nil {
+			return err
+		}
+	}
+
+	if len(create) > 0 {
+		for i := range create {
+			create[i].HostID = host.HostID
+		}
+
+		log.Printf("[DEBUG] Creating interfaces %v", create)
+
+		if err := api.HostInterfacesCreate(create); err != nil {
+			return err
+		}
+	}
+
+	return resourceZabbixHostRead(d, meta)
 }
 
 func resourceZabbixHostDelete(d *schema.ResourceData, meta interface{}) error {
@@ -440,22 +725,162 @@ func resourceZabbixHostDelete(d *schema.ResourceData, meta interface{}) error {
 	return api.HostsDeleteByIds([]string{d.Id()})
 }
 
-func createTerraformMacroOnHost(host zabbix.Host) (map[string]interface{}, error) {
-	terraformMacros := make(map[string]interface{}, len(host.UserMacros))
+func flattenHostInterfaces(interfaces zabbix.HostInterfaces) []map[string]interface{} {
+	terraformInterfaces := make([]map[string]interface{}, len(interfaces))
+
+	for i, iface := range interfaces {
+		interfaceType := "agent"
+
+		for name, typeID := range HostInterfaceTypes {
+			if typeID == iface.Type {
+				interfaceType = name
+				break
+			}
+		}
+
+		terraformInterfaces[i] = map[string]interface{}{
+			"interface_id": iface.InterfaceID,
+			"ip":           iface.IP,
+			"dns":          iface.DNS,
+			"main":         iface.Main == 1,
+			"port":         iface.Port,
+			"type":         interfaceType,
+		}
+
+		if interfaceType == "snmp" {
+			terraformInterfaces[i]["details"] = []map[string]interface{}{
+				flattenHostInterfaceDetails(iface.Details),
+			}
+		}
+	}
+
+	return terraformInterfaces
+}
+
+func flattenHostInterfaceDetails(details zabbix.HostInterfaceDetails) map[string]interface{} {
+	securityLevel := ""
+
+	for name, levelID := range SNMPSecurityLevels {
+		if levelID == details.SecurityLevel {
+			securityLevel = name
+			break
+		}
+	}
+
+	authProtocol := ""
+
+	for name, protocolID := range SNMPAuthProtocols {
+		if protocolID == details.AuthProtocol {
+			authProtocol = name
+			break
+		}
+	}
+
+	privProtocol := ""
+
+	for name, protocolID := range SNMPPrivProtocols {
+		if protocolID == details.PrivProtocol {
+			privProtocol = name
+			break
+		}
+	}
+
+	return map[string]interface{}{
+		"version":        details.Version,
+		"bulk":           details.Bulk == 1,
+		"community":      details.Community,
+		"securityname":   details.SecurityName,
+		"securitylevel":  securityLevel,
+		"authpassphrase": details.AuthPassphrase,
+		"privpassphrase": details.PrivPassphrase,
+		"authprotocol":   authProtocol,
+		"privprotocol":   privProtocol,
+		"contextname":    details.ContextName,
+	}
+}
+
+// macroNameRegexp strips the "{$" / "}" wrapper off a zabbix macro name,
+// keeping everything in between intact - including context macros like
+// {$FOO:"ctx"} where a naive strings.Split on "{$"/"}" falls over as soon as
+// the context itself contains one of those characters
+var macroNameRegexp = regexp.MustCompile(`^\{\$(.+)\}$`)
+
+func createZabbixMacro(d *schema.ResourceData) zabbix.Macros {
+	configMacros := d.Get("macro").(*schema.Set)
+
+	macros := make(zabbix.Macros, 0, configMacros.Len())
+
+	for _, raw := range configMacros.List() {
+		m := raw.(map[string]interface{})
+
+		macroType, ok := MacroTypes[m["type"].(string)]
+
+		if !ok {
+			macroType = MacroTypes["text"]
+		}
+
+		macros = append(macros, zabbix.Macro{
+			MacroName:   fmt.Sprintf("{$%s}", m["name"].(string)),
+			Value:       m["value"].(string),
+			Type:        macroType,
+			Description: m["description"].(string),
+		})
+	}
+
+	return macros
+}
+
+func createTerraformMacroOnHost(d *schema.ResourceData, host zabbix.Host) ([]interface{}, error) {
+	previousMacros := d.Get("macro").(*schema.Set)
+
+	terraformMacros := make([]interface{}, 0, len(host.UserMacros))
 
 	for _, macro := range host.UserMacros {
-		var name string
-		if noPrefix := strings.Split(macro.MacroName, "{$"); len(noPrefix) == 2 {
-			name = noPrefix[1]
-		} else {
+		matches := macroNameRegexp.FindStringSubmatch(macro.MacroName)
+
+		if matches == nil {
 			return nil, fmt.Errorf("Invalid macro name \"%s\"", macro.MacroName)
 		}
-		if noSuffix := strings.Split(name, "}"); len(noSuffix) == 2 {
-			name = noSuffix[0]
-		} else {
-			return nil, fmt.Errorf("Invalid macro name \"%s\"", macro.MacroName)
+
+		name := matches[1]
+
+		macroType := "text"
+
+		for typeName, typeID := range MacroTypes {
+			if typeID == macro.Type {
+				macroType = typeName
+				break
+			}
+		}
+
+		value := macro.Value
+
+		if macroType == "secret" && value == zabbixMacroSecretPlaceholder {
+			value = previousMacroValue(previousMacros, name)
 		}
-		terraformMacros[name] = macro.Value
+
+		terraformMacros = append(terraformMacros, map[string]interface{}{
+			"name":        name,
+			"value":       value,
+			"type":        macroType,
+			"description": macro.Description,
+		})
 	}
+
 	return terraformMacros, nil
 }
+
+// previousMacroValue looks up the value terraform last knew for a macro by
+// name, so a redacted secret value returned by the API doesn't get written
+// back into state and show up as permanent drift
+func previousMacroValue(previousMacros *schema.Set, name string) string {
+	for _, raw := range previousMacros.List() {
+		m := raw.(map[string]interface{})
+
+		if m["name"].(string) == name {
+			return m["value"].(string)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,293 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// HostPrototypeInventoryModes zabbix inventory population mode for a host prototype
+var HostPrototypeInventoryModes = map[string]int{
+	"disabled":  -1,
+	"manual":    0,
+	"automatic": 1,
+}
+
+var groupPrototypeSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Group name template, evaluated per discovered LLD row, e.g. \"{#FSNAME}\".",
+		},
+	},
+}
+
+func resourceZabbixHostPrototype() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixHostPrototypeCreate,
+		Read:   resourceZabbixHostPrototypeRead,
+		Update: resourceZabbixHostPrototypeUpdate,
+		Delete: resourceZabbixHostPrototypeDelete,
+		Schema: map[string]*schema.Schema{
+			"ruleid": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the parent low-level discovery rule.",
+			},
+			"host": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Technical name of the host prototype, may contain LLD macros like {#MACRO}.",
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"monitored": &schema.Schema{
+				Type:     schema.TypeBool,
+				Default:  true,
+				Optional: true,
+			},
+			"inventory_mode": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "disabled",
+			},
+			"groups": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"group_prototypes": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     groupPrototypeSchema,
+				Optional: true,
+			},
+			"templates": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"macro": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        macroSchema,
+				Optional:    true,
+				Description: "User macros for the host prototype.",
+			},
+		},
+	}
+}
+
+func getGroupPrototypes(d *schema.ResourceData) zabbix.GroupPrototypes {
+	count := d.Get("group_prototypes.#").(int)
+
+	prototypes := make(zabbix.GroupPrototypes, count)
+
+	for i := 0; i < count; i++ {
+		prototypes[i] = zabbix.GroupPrototype{
+			Name: d.Get(fmt.Sprintf("group_prototypes.%d.name", i)).(string),
+		}
+	}
+
+	return prototypes
+}
+
+func createHostPrototypeObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.HostPrototype, error) {
+	inventoryMode, ok := HostPrototypeInventoryModes[d.Get("inventory_mode").(string)]
+
+	if !ok {
+		return nil, fmt.Errorf("%s isnt valid inventory mode", d.Get("inventory_mode").(string))
+	}
+
+	hostPrototype := zabbix.HostPrototype{
+		RuleID:        d.Get("ruleid").(string),
+		Host:          d.Get("host").(string),
+		Name:          d.Get("name").(string),
+		InventoryMode: inventoryMode,
+		UserMacros:    createZabbixMacro(d),
+	}
+
+	if !d.Get("monitored").(bool) {
+		hostPrototype.Status = 1
+	}
+
+	groups, err := getHostGroups(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hostPrototype.GroupLinks = groups
+	hostPrototype.GroupPrototypes = getGroupPrototypes(d)
+
+	templates, err := getTemplates(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hostPrototype.TemplateIDs = templates
+
+	if hostPrototype.UserMacros == nil {
+		hostPrototype.UserMacros = zabbix.Macros{}
+	}
+
+	return &hostPrototype, nil
+}
+
+func resourceZabbixHostPrototypeCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	hostPrototype, err := createHostPrototypeObj(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	hostPrototypes := zabbix.HostPrototypes{*hostPrototype}
+
+	err = api.HostPrototypesCreate(hostPrototypes)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Created host prototype id is %s", hostPrototypes[0].HostID)
+
+	d.SetId(hostPrototypes[0].HostID)
+
+	return nil
+}
+
+func resourceZabbixHostPrototypeRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	log.Printf("[DEBUG] Will read host prototype with id %s", d.Id())
+
+	hostPrototype, err := api.HostPrototypeGetByID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("ruleid", hostPrototype.RuleID)
+	d.Set("host", hostPrototype.Host)
+	d.Set("name", hostPrototype.Name)
+	d.Set("monitored", hostPrototype.Status == 0)
+
+	for name, modeID := range HostPrototypeInventoryModes {
+		if modeID == hostPrototype.InventoryMode {
+			d.Set("inventory_mode", name)
+			break
+		}
+	}
+
+	terraformMacros, err := createTerraformMacroOnHost(d, zabbix.Host{UserMacros: hostPrototype.UserMacros})
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("macro", terraformMacros)
+
+	if len(hostPrototype.GroupLinks) > 0 {
+		groupIDs := make([]string, len(hostPrototype.GroupLinks))
+
+		for i, g := range hostPrototype.GroupLinks {
+			groupIDs[i] = g.GroupID
+		}
+
+		groups, err := api.HostGroupsGet(zabbix.Params{
+			"output":   "extend",
+			"groupids": groupIDs,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		groupNames := make([]string, len(groups))
+
+		for i, g := range groups {
+			groupNames[i] = g.Name
+		}
+
+		d.Set("groups", groupNames)
+	} else {
+		d.Set("groups", []string{})
+	}
+
+	terraformGroupPrototypes := make([]map[string]interface{}, len(hostPrototype.GroupPrototypes))
+
+	for i, gp := range hostPrototype.GroupPrototypes {
+		terraformGroupPrototypes[i] = map[string]interface{}{
+			"name": gp.Name,
+		}
+	}
+
+	d.Set("group_prototypes", terraformGroupPrototypes)
+
+	if len(hostPrototype.TemplateIDs) > 0 {
+		templateIDs := make([]string, len(hostPrototype.TemplateIDs))
+
+		for i, t := range hostPrototype.TemplateIDs {
+			templateIDs[i] = t.TemplateID
+		}
+
+		templates, err := api.TemplatesGet(zabbix.Params{
+			"output":      "extend",
+			"templateids": templateIDs,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		templateNames := make([]string, len(templates))
+
+		for i, t := range templates {
+			templateNames[i] = t.Host
+		}
+
+		d.Set("templates", templateNames)
+	} else {
+		d.Set("templates", []string{})
+	}
+
+	return nil
+}
+
+func resourceZabbixHostPrototypeUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	hostPrototype, err := createHostPrototypeObj(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	hostPrototype.HostID = d.Id()
+
+	hostPrototypes := zabbix.HostPrototypes{*hostPrototype}
+
+	err = api.HostPrototypesUpdate(hostPrototypes)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Updated host prototype id is %s", hostPrototypes[0].HostID)
+
+	return nil
+}
+
+func resourceZabbixHostPrototypeDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	return api.HostPrototypesDeleteByIds([]string{d.Id()})
+}
@@ -0,0 +1,114 @@
+package zabbix
+
+import "testing"
+
+// This is a unit test against diffInterfaceLists, the pure dispatch logic
+// behind resourceZabbixHostUpdate. It doesn't exercise a real Zabbix server
+// or provider test harness (this repo has neither), so it can't observe an
+// actual item/trigger surviving an API round trip. What it does prove is the
+// precondition for that: when an interface's ip changes but its
+// interface_id is still known, diffInterfaceLists must route it through
+// hostinterface.update (carrying the same interface_id forward) instead of
+// pairing a hostinterface.delete with a hostinterface.create, which is what
+// would orphan anything linked to the old interface.
+//
+// TODO: this only covers diffInterfaceLists in isolation. Whether an item or
+// trigger linked to the interface actually survives the real
+// hostinterface.update call against a live Zabbix server is still
+// unverified end-to-end - there's no acceptance test harness in this repo
+// to exercise that with.
+func TestDiffInterfaceListsKeepsInterfaceIDOnIPChange(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{
+			"interface_id": "10022",
+			"type":         "agent",
+			"main":         true,
+			"ip":           "10.0.0.1",
+			"dns":          "",
+			"port":         "10050",
+			"details":      []interface{}{},
+		},
+	}
+
+	new := []interface{}{
+		map[string]interface{}{
+			"interface_id": "10022",
+			"type":         "agent",
+			"main":         true,
+			"ip":           "10.0.0.2",
+			"dns":          "",
+			"port":         "10050",
+			"details":      []interface{}{},
+		},
+	}
+
+	create, update, deleteIDs, err := diffInterfaceLists(old, new)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(create) != 0 || len(deleteIDs) != 0 {
+		t.Fatalf("expected the interface to be updated in place, got create=%v delete=%v", create, deleteIDs)
+	}
+
+	if len(update) != 1 {
+		t.Fatalf("expected exactly one interface to be updated, got %d", len(update))
+	}
+
+	if update[0].InterfaceID != "10022" {
+		t.Fatalf("expected interface_id 10022 to be preserved, got %q", update[0].InterfaceID)
+	}
+
+	if update[0].IP != "10.0.0.2" {
+		t.Fatalf("expected the new ip to be sent, got %q", update[0].IP)
+	}
+}
+
+// A config-only interface (no interface_id yet) with no matching tuple in
+// state is a genuinely new interface and must be created, not matched
+// against an unrelated existing one.
+func TestDiffInterfaceListsCreatesUnmatchedInterface(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{
+			"interface_id": "10022",
+			"type":         "agent",
+			"main":         true,
+			"ip":           "10.0.0.1",
+			"dns":          "",
+			"port":         "10050",
+			"details":      []interface{}{},
+		},
+	}
+
+	new := []interface{}{
+		old[0],
+		map[string]interface{}{
+			"interface_id": "",
+			"type":         "snmp",
+			"main":         false,
+			"ip":           "10.0.0.5",
+			"dns":          "",
+			"port":         "161",
+			"details":      []interface{}{},
+		},
+	}
+
+	create, update, deleteIDs, err := diffInterfaceLists(old, new)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(deleteIDs) != 0 {
+		t.Fatalf("expected nothing to be deleted, got %v", deleteIDs)
+	}
+
+	if len(update) != 1 || update[0].InterfaceID != "10022" {
+		t.Fatalf("expected the existing interface to be updated in place, got %v", update)
+	}
+
+	if len(create) != 1 || create[0].IP != "10.0.0.5" {
+		t.Fatalf("expected the new snmp interface to be created, got %v", create)
+	}
+}
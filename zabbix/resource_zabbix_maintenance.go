@@ -0,0 +1,414 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// MaintenanceTypes zabbix maintenance type, with or without data collection
+var MaintenanceTypes = map[string]int{
+	"with_data":    0,
+	"without_data": 1,
+}
+
+// MaintenanceTimePeriodTypes zabbix timeperiod type for a maintenance window
+var MaintenanceTimePeriodTypes = map[string]int{
+	"one_time": 0,
+	"daily":    2,
+	"weekly":   3,
+	"monthly":  4,
+}
+
+var maintenanceTimePeriodSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"type": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "one_time",
+		},
+		"every": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  1,
+		},
+		"dayofweek": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"month": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"day": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"start_time": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"period": &schema.Schema{
+			Type:     schema.TypeInt,
+			Required: true,
+		},
+	},
+}
+
+func resourceZabbixMaintenance() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixMaintenanceCreate,
+		Read:   resourceZabbixMaintenanceRead,
+		Update: resourceZabbixMaintenanceUpdate,
+		Delete: resourceZabbixMaintenanceDelete,
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the maintenance period.",
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"active_since": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "RFC3339 timestamp the maintenance becomes active.",
+				DiffSuppressFunc: suppressEquivalentRFC3339Diff,
+			},
+			"active_till": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "RFC3339 timestamp the maintenance stops being active.",
+				DiffSuppressFunc: suppressEquivalentRFC3339Diff,
+			},
+			"maintenance_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "with_data",
+			},
+			"host": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"groups": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"timeperiods": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     maintenanceTimePeriodSchema,
+				Required: true,
+			},
+		},
+	}
+}
+
+// suppressEquivalentRFC3339Diff avoids a permanent diff for active_since/
+// active_till: zabbix only stores a unix timestamp, so active_since/
+// active_till are always read back formatted in UTC, which would otherwise
+// never match a config written with a non-UTC offset even though the
+// instant in time is identical
+func suppressEquivalentRFC3339Diff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	oldTime, err := time.Parse(time.RFC3339, oldValue)
+
+	if err != nil {
+		return false
+	}
+
+	newTime, err := time.Parse(time.RFC3339, newValue)
+
+	if err != nil {
+		return false
+	}
+
+	return oldTime.Equal(newTime)
+}
+
+func getHosts(d *schema.ResourceData, api *zabbix.API) (zabbix.HostIDs, error) {
+	configHosts := d.Get("host").(*schema.Set)
+	setHosts := make([]string, configHosts.Len())
+
+	for i, h := range configHosts.List() {
+		setHosts[i] = h.(string)
+	}
+
+	log.Printf("[DEBUG] Hosts %v\n", setHosts)
+
+	hostParams := zabbix.Params{
+		"output": "extend",
+		"filter": map[string]interface{}{
+			"host": setHosts,
+		},
+	}
+
+	hosts, err := api.HostsGet(hostParams)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hosts) < configHosts.Len() {
+		log.Printf("[DEBUG] Not all of the specified hosts were found on zabbix server")
+
+		for _, n := range configHosts.List() {
+			found := false
+
+			for _, h := range hosts {
+				if n == h.Host {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("Host %s doesnt exist in zabbix server", n)
+			}
+			log.Printf("[DEBUG] %s exists on zabbix server", n)
+		}
+	}
+
+	hostIDs := make(zabbix.HostIDs, len(hosts))
+
+	for i, h := range hosts {
+		hostIDs[i] = zabbix.HostID{
+			HostID: h.HostID,
+		}
+	}
+
+	return hostIDs, nil
+}
+
+func getMaintenanceTimePeriods(d *schema.ResourceData) (zabbix.TimePeriods, error) {
+	periodCount := d.Get("timeperiods.#").(int)
+
+	periods := make(zabbix.TimePeriods, periodCount)
+
+	for i := 0; i < periodCount; i++ {
+		prefix := fmt.Sprintf("timeperiods.%d.", i)
+
+		periodType := d.Get(prefix + "type").(string)
+
+		typeID, ok := MaintenanceTimePeriodTypes[periodType]
+
+		if !ok {
+			return nil, fmt.Errorf("%s isnt valid timeperiod type", periodType)
+		}
+
+		periods[i] = zabbix.TimePeriod{
+			TimePeriodType: typeID,
+			Every:          d.Get(prefix + "every").(int),
+			DayOfWeek:      d.Get(prefix + "dayofweek").(int),
+			Month:          d.Get(prefix + "month").(int),
+			Day:            d.Get(prefix + "day").(int),
+			StartTime:      d.Get(prefix + "start_time").(int),
+			Period:         d.Get(prefix + "period").(int),
+		}
+	}
+
+	return periods, nil
+}
+
+func flattenMaintenanceTimePeriods(periods zabbix.TimePeriods) []map[string]interface{} {
+	terraformPeriods := make([]map[string]interface{}, len(periods))
+
+	for i, period := range periods {
+		periodType := "one_time"
+
+		for name, typeID := range MaintenanceTimePeriodTypes {
+			if typeID == period.TimePeriodType {
+				periodType = name
+				break
+			}
+		}
+
+		terraformPeriods[i] = map[string]interface{}{
+			"type":       periodType,
+			"every":      period.Every,
+			"dayofweek":  period.DayOfWeek,
+			"month":      period.Month,
+			"day":        period.Day,
+			"start_time": period.StartTime,
+			"period":     period.Period,
+		}
+	}
+
+	return terraformPeriods
+}
+
+func createMaintenanceObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.Maintenance, error) {
+	activeSince, err := time.Parse(time.RFC3339, d.Get("active_since").(string))
+
+	if err != nil {
+		return nil, fmt.Errorf("active_since is not a valid RFC3339 timestamp: %s", err)
+	}
+
+	activeTill, err := time.Parse(time.RFC3339, d.Get("active_till").(string))
+
+	if err != nil {
+		return nil, fmt.Errorf("active_till is not a valid RFC3339 timestamp: %s", err)
+	}
+
+	maintenanceType, ok := MaintenanceTypes[d.Get("maintenance_type").(string)]
+
+	if !ok {
+		return nil, fmt.Errorf("%s isnt valid maintenance type", d.Get("maintenance_type").(string))
+	}
+
+	maintenance := zabbix.Maintenance{
+		Name:            d.Get("name").(string),
+		Description:     d.Get("description").(string),
+		ActiveSince:     activeSince.Unix(),
+		ActiveTill:      activeTill.Unix(),
+		MaintenanceType: maintenanceType,
+	}
+
+	groups, err := getHostGroups(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maintenance.Groups = groups
+
+	hosts, err := getHosts(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maintenance.Hosts = hosts
+
+	timeperiods, err := getMaintenanceTimePeriods(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maintenance.TimePeriods = timeperiods
+
+	return &maintenance, nil
+}
+
+func resourceZabbixMaintenanceCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	maintenance, err := createMaintenanceObj(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	maintenances := zabbix.Maintenances{*maintenance}
+
+	err = api.MaintenancesCreate(maintenances)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Created maintenance id is %s", maintenances[0].MaintenanceID)
+
+	d.SetId(maintenances[0].MaintenanceID)
+
+	return nil
+}
+
+func resourceZabbixMaintenanceRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	log.Printf("[DEBUG] Will read maintenance with id %s", d.Id())
+
+	maintenance, err := api.MaintenanceGetByID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", maintenance.Name)
+	d.Set("description", maintenance.Description)
+	d.Set("active_since", time.Unix(maintenance.ActiveSince, 0).UTC().Format(time.RFC3339))
+	d.Set("active_till", time.Unix(maintenance.ActiveTill, 0).UTC().Format(time.RFC3339))
+
+	for name, id := range MaintenanceTypes {
+		if id == maintenance.MaintenanceType {
+			d.Set("maintenance_type", name)
+			break
+		}
+	}
+
+	maintenanceParams := zabbix.Params{
+		"output": "extend",
+		"maintenanceids": []string{
+			d.Id(),
+		},
+	}
+
+	hosts, err := api.HostsGet(maintenanceParams)
+
+	if err != nil {
+		return err
+	}
+
+	hostNames := make([]string, len(hosts))
+
+	for i, h := range hosts {
+		hostNames[i] = h.Host
+	}
+
+	d.Set("host", hostNames)
+
+	groups, err := api.HostGroupsGet(maintenanceParams)
+
+	if err != nil {
+		return err
+	}
+
+	groupNames := make([]string, len(groups))
+
+	for i, g := range groups {
+		groupNames[i] = g.Name
+	}
+
+	d.Set("groups", groupNames)
+
+	d.Set("timeperiods", flattenMaintenanceTimePeriods(maintenance.TimePeriods))
+
+	return nil
+}
+
+func resourceZabbixMaintenanceUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	maintenance, err := createMaintenanceObj(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	maintenance.MaintenanceID = d.Id()
+
+	maintenances := zabbix.Maintenances{*maintenance}
+
+	err = api.MaintenancesUpdate(maintenances)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Updated maintenance id is %s", maintenances[0].MaintenanceID)
+
+	return nil
+}
+
+func resourceZabbixMaintenanceDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*zabbix.API)
+
+	return api.MaintenancesDeleteByIds([]string{d.Id()})
+}